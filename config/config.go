@@ -0,0 +1,7 @@
+package config
+
+// Config represents a parsed Terraform configuration, such as a single
+// module's worth of *.tf files merged together.
+type Config struct {
+	Variables []*Variable
+}
@@ -0,0 +1,28 @@
+// Package module has support for loading Terraform modules via various
+// means, and the module tree used to reference which module belongs to
+// which path within the configuration.
+package module
+
+import "github.com/hashicorp/terraform/config"
+
+// Tree represents the module tree for a Terraform configuration: the
+// root module's configuration plus, recursively, each module it calls.
+type Tree struct {
+	config   *config.Config
+	children map[string]*Tree
+}
+
+// NewTree returns a Tree for the given configuration and children.
+func NewTree(c *config.Config, children map[string]*Tree) *Tree {
+	return &Tree{config: c, children: children}
+}
+
+// Config returns the configuration for this module tree node.
+func (t *Tree) Config() *config.Config {
+	return t.config
+}
+
+// Children returns the child module trees, keyed by their path component.
+func (t *Tree) Children() map[string]*Tree {
+	return t.children
+}
@@ -0,0 +1,327 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kind is the fundamental shape of a declared or inferred variable type.
+type Kind byte
+
+const (
+	KindAny Kind = iota
+	KindString
+	KindBool
+	KindNumber
+	KindList
+	KindMap
+	KindObject
+	KindTuple
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindBool:
+		return "bool"
+	case KindNumber:
+		return "number"
+	case KindList:
+		return "list"
+	case KindMap:
+		return "map"
+	case KindObject:
+		return "object"
+	case KindTuple:
+		return "tuple"
+	default:
+		return "any"
+	}
+}
+
+// TypeSpec is a variable's declared or inferred type. Unlike the legacy
+// VariableType enum, which only names the outer Go representation
+// (string/list/map), a TypeSpec can describe the structure nested inside
+// a list or map's element type, an object's per-attribute types, a
+// tuple's per-position types, and whether null is an acceptable value.
+type TypeSpec struct {
+	Kind       Kind
+	Element    *TypeSpec            // list(...) / map(...)
+	Attributes map[string]*TypeSpec // object({...})
+	Elements   []*TypeSpec          // tuple([...])
+	Nullable   bool
+}
+
+// String renders the type the way it would appear in a `type = ...`
+// declaration, so error messages can name a nested type rather than just
+// the outer Go kind.
+func (t *TypeSpec) String() string {
+	if t == nil {
+		return "any"
+	}
+
+	var s string
+	switch t.Kind {
+	case KindList:
+		s = fmt.Sprintf("list(%s)", t.Element.String())
+	case KindMap:
+		s = fmt.Sprintf("map(%s)", t.Element.String())
+	case KindObject:
+		names := make([]string, 0, len(t.Attributes))
+		for name := range t.Attributes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		attrs := make([]string, len(names))
+		for i, name := range names {
+			attrs[i] = fmt.Sprintf("%s=%s", name, t.Attributes[name].String())
+		}
+		s = fmt.Sprintf("object({%s})", strings.Join(attrs, ", "))
+	case KindTuple:
+		elems := make([]string, len(t.Elements))
+		for i, et := range t.Elements {
+			elems[i] = et.String()
+		}
+		s = fmt.Sprintf("tuple([%s])", strings.Join(elems, ", "))
+	default:
+		s = t.Kind.String()
+	}
+
+	if t.Nullable {
+		s = fmt.Sprintf("nullable(%s)", s)
+	}
+	return s
+}
+
+// ParseTypeSpec parses a `type = ...` declaration such as
+// `list(object({name=string, port=number}))` into a TypeSpec. It covers
+// the subset of the type expression grammar variable blocks use: the
+// scalar keywords string/bool/number/any, list(...), map(...),
+// object({...}), tuple([...]), and nullable(...) wrapping any of those.
+func ParseTypeSpec(src string) (*TypeSpec, error) {
+	p := &typeSpecParser{src: src}
+	t, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.src) {
+		return nil, fmt.Errorf("unexpected trailing characters %q", p.src[p.pos:])
+	}
+	return t, nil
+}
+
+type typeSpecParser struct {
+	src string
+	pos int
+}
+
+func (p *typeSpecParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+func (p *typeSpecParser) readIdent() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) && isIdentByte(p.src[p.pos]) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *typeSpecParser) expect(b byte) error {
+	p.skipSpace()
+	if p.pos >= len(p.src) || p.src[p.pos] != b {
+		return fmt.Errorf("expected %q at position %d in %q", b, p.pos, p.src)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *typeSpecParser) parseType() (*TypeSpec, error) {
+	ident := p.readIdent()
+	switch ident {
+	case "":
+		return nil, fmt.Errorf("expected a type keyword at position %d in %q", p.pos, p.src)
+	case "any":
+		return &TypeSpec{Kind: KindAny}, nil
+	case "string":
+		return &TypeSpec{Kind: KindString}, nil
+	case "bool":
+		return &TypeSpec{Kind: KindBool}, nil
+	case "number":
+		return &TypeSpec{Kind: KindNumber}, nil
+	case "nullable":
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		inner.Nullable = true
+		return inner, nil
+	case "list":
+		elem, err := p.parseParenType()
+		if err != nil {
+			return nil, err
+		}
+		return &TypeSpec{Kind: KindList, Element: elem}, nil
+	case "map":
+		elem, err := p.parseParenType()
+		if err != nil {
+			return nil, err
+		}
+		return &TypeSpec{Kind: KindMap, Element: elem}, nil
+	case "object":
+		return p.parseObjectType()
+	case "tuple":
+		return p.parseTupleType()
+	default:
+		return nil, fmt.Errorf("unknown type keyword %q at position %d in %q", ident, p.pos, p.src)
+	}
+}
+
+func (p *typeSpecParser) parseParenType() (*TypeSpec, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	elem, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return elem, nil
+}
+
+func (p *typeSpecParser) parseObjectType() (*TypeSpec, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string]*TypeSpec)
+	p.skipSpace()
+	for p.pos < len(p.src) && p.src[p.pos] != '}' {
+		name := p.readIdent()
+		if name == "" {
+			return nil, fmt.Errorf("expected an attribute name at position %d in %q", p.pos, p.src)
+		}
+		if err := p.expect('='); err != nil {
+			return nil, err
+		}
+		attrType, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		attrs[name] = attrType
+
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+			p.skipSpace()
+		}
+	}
+
+	if err := p.expect('}'); err != nil {
+		return nil, err
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+
+	return &TypeSpec{Kind: KindObject, Attributes: attrs}, nil
+}
+
+func (p *typeSpecParser) parseTupleType() (*TypeSpec, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	if err := p.expect('['); err != nil {
+		return nil, err
+	}
+
+	var elems []*TypeSpec
+	p.skipSpace()
+	for p.pos < len(p.src) && p.src[p.pos] != ']' {
+		elemType, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, elemType)
+
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+			p.skipSpace()
+		}
+	}
+
+	if err := p.expect(']'); err != nil {
+		return nil, err
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+
+	return &TypeSpec{Kind: KindTuple, Elements: elems}, nil
+}
+
+// InferTypeSpec derives a TypeSpec from a variable's default value, used
+// when no explicit `type = ...` was declared. A list whose elements all
+// share a Kind infers as list(elementKind); a list with mixed element
+// kinds infers as a tuple instead, since there's no single element type
+// to name. A nil default infers as a nullable "any".
+func InferTypeSpec(v interface{}) *TypeSpec {
+	switch v := v.(type) {
+	case nil:
+		return &TypeSpec{Kind: KindAny, Nullable: true}
+	case bool:
+		return &TypeSpec{Kind: KindBool}
+	case int, int64, float64:
+		return &TypeSpec{Kind: KindNumber}
+	case string:
+		return &TypeSpec{Kind: KindString}
+	case []interface{}:
+		elems := make([]*TypeSpec, len(v))
+		uniform := true
+		for i, e := range v {
+			elems[i] = InferTypeSpec(e)
+			if i > 0 && elems[i].Kind != elems[0].Kind {
+				uniform = false
+			}
+		}
+		if uniform && len(elems) > 0 {
+			return &TypeSpec{Kind: KindList, Element: elems[0]}
+		}
+		return &TypeSpec{Kind: KindTuple, Elements: elems}
+	case map[string]interface{}:
+		attrs := make(map[string]*TypeSpec, len(v))
+		for k, e := range v {
+			attrs[k] = InferTypeSpec(e)
+		}
+		return &TypeSpec{Kind: KindObject, Attributes: attrs}
+	default:
+		return &TypeSpec{Kind: KindAny}
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import "testing"
+
+func TestParseTypeSpec(t *testing.T) {
+	cases := []struct {
+		Src  string
+		Want string
+	}{
+		{"string", "string"},
+		{"bool", "bool"},
+		{"number", "number"},
+		{"any", "any"},
+		{"list(string)", "list(string)"},
+		{"map(number)", "map(number)"},
+		{"list(list(string))", "list(list(string))"},
+		{"nullable(string)", "nullable(string)"},
+		{"object({name=string, port=number})", "object({name=string, port=number})"},
+		{"tuple([string, number])", "tuple([string, number])"},
+		{"list(object({name=string, port=number}))", "list(object({name=string, port=number}))"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Src, func(t *testing.T) {
+			got, err := ParseTypeSpec(tc.Src)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got.String() != tc.Want {
+				t.Fatalf("got %q, want %q", got.String(), tc.Want)
+			}
+		})
+	}
+}
+
+func TestParseTypeSpec_errors(t *testing.T) {
+	cases := []string{
+		"",
+		"strng",
+		"list(string",
+		"object({name=string)",
+		"string extra",
+	}
+
+	for _, src := range cases {
+		t.Run(src, func(t *testing.T) {
+			if _, err := ParseTypeSpec(src); err == nil {
+				t.Fatalf("expected an error parsing %q", src)
+			}
+		})
+	}
+}
+
+func TestInferTypeSpec(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Value interface{}
+		Want  string
+	}{
+		{"nil", nil, "nullable(any)"},
+		{"bool", true, "bool"},
+		{"number", 42, "number"},
+		{"string", "a", "string"},
+		{"uniform list", []interface{}{"a", "b"}, "list(string)"},
+		{"mixed list infers tuple", []interface{}{"a", 1}, "tuple([string, number])"},
+		{"map infers object", map[string]interface{}{"name": "web"}, "object({name=string})"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := InferTypeSpec(tc.Value).String(); got != tc.Want {
+				t.Fatalf("got %q, want %q", got, tc.Want)
+			}
+		})
+	}
+}
+
+func TestVariable_typeSpec(t *testing.T) {
+	v := &Variable{DeclaredType: "list(object({name=string, port=number}))"}
+	spec, err := v.TypeSpec()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := spec.String(), "list(object({name=string, port=number}))"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	legacyList := &Variable{DeclaredType: "list"}
+	spec, err = legacyList.TypeSpec()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := spec.String(), "list(any)"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	inferred := &Variable{Default: []interface{}{"a", "b"}}
+	spec, err = inferred.TypeSpec()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := spec.String(), "list(string)"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+package config
+
+// UnknownVariableValue is a sentinel value that can be used
+// to denote that the value of a variable is unknown at this time.
+// RawConfig uses this information to build up data about
+// unknown keys.
+const UnknownVariableValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+// VariableType is the type of value a variable is holding, as declared on
+// a "variable" block.
+type VariableType byte
+
+const (
+	VariableTypeInvalid VariableType = iota
+	VariableTypeString
+	VariableTypeList
+	VariableTypeMap
+)
+
+func (v VariableType) Printable() string {
+	switch v {
+	case VariableTypeString:
+		return "string"
+	case VariableTypeList:
+		return "list"
+	case VariableTypeMap:
+		return "map"
+	default:
+		return "unknown"
+	}
+}
+
+// Variable represents a variable defined within the configuration, such as
+// one declared with a "variable" block.
+type Variable struct {
+	Name         string
+	DeclaredType string `mapstructure:"type"`
+	Default      interface{}
+	Description  string
+
+	// Sensitive marks the variable as holding a value that should be
+	// redacted wherever Terraform formats it for a human: log lines,
+	// plan output and state diffs.
+	Sensitive bool
+
+	// Validations are user-defined checks, declared with nested
+	// `validation` blocks, run against the variable's proposed value
+	// after type checking succeeds.
+	Validations []VariableValidation
+}
+
+// Type returns the type of variable this is, either inferred from the
+// Default value or explicitly declared via DeclaredType. Only the bare
+// keywords "string", "list" and "map" count as a declaration here; any
+// other DeclaredType, such as a full type expression, falls back to
+// inferring from Default. TypeSpec covers the full type grammar.
+func (v *Variable) Type() VariableType {
+	switch v.DeclaredType {
+	case "string":
+		return VariableTypeString
+	case "list":
+		return VariableTypeList
+	case "map":
+		return VariableTypeMap
+	}
+
+	return typeForValue(v.Default)
+}
+
+// TypeSpec returns the full declared or inferred type of this variable,
+// including structure Type/VariableType can't express: a list's element
+// type, an object's per-attribute types, a tuple's per-position types,
+// and nullability. The bare legacy keywords "list" and "map" are still
+// accepted and resolve to a list/map of any; anything else in
+// DeclaredType is parsed as a full type expression such as
+// "list(object({name=string, port=number}))".
+func (v *Variable) TypeSpec() (*TypeSpec, error) {
+	switch v.DeclaredType {
+	case "":
+		return InferTypeSpec(v.Default), nil
+	case "list":
+		return &TypeSpec{Kind: KindList, Element: &TypeSpec{Kind: KindAny}}, nil
+	case "map":
+		return &TypeSpec{Kind: KindMap, Element: &TypeSpec{Kind: KindAny}}, nil
+	}
+
+	return ParseTypeSpec(v.DeclaredType)
+}
+
+func typeForValue(v interface{}) VariableType {
+	switch v.(type) {
+	case []interface{}:
+		return VariableTypeList
+	case map[string]interface{}:
+		return VariableTypeMap
+	default:
+		return VariableTypeString
+	}
+}
+
+// VariableValidation is a single user-defined validation rule attached to
+// a variable block. Condition is compiled from the block's `condition`
+// expression at config-load time into a predicate over the variable's
+// proposed value, which the expression refers to as var.self; ErrorMessage
+// is the message reported when Condition returns false.
+type VariableValidation struct {
+	Condition    func(self interface{}) (bool, error)
+	ErrorMessage string
+}
+
+// Check evaluates the validation's condition against the proposed value.
+func (v *VariableValidation) Check(self interface{}) (bool, error) {
+	return v.Condition(self)
+}
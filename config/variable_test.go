@@ -0,0 +1,63 @@
+package config
+
+import "testing"
+
+func TestVariable_type(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Variable *Variable
+		Want     VariableType
+	}{
+		{"declared string", &Variable{DeclaredType: "string"}, VariableTypeString},
+		{"declared list", &Variable{DeclaredType: "list"}, VariableTypeList},
+		{"declared map", &Variable{DeclaredType: "map"}, VariableTypeMap},
+		{"inferred from list default", &Variable{Default: []interface{}{"a"}}, VariableTypeList},
+		{"inferred from map default", &Variable{Default: map[string]interface{}{"a": "b"}}, VariableTypeMap},
+		{"inferred from string default", &Variable{Default: "a"}, VariableTypeString},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := tc.Variable.Type(); got != tc.Want {
+				t.Fatalf("got %s, want %s", got.Printable(), tc.Want.Printable())
+			}
+		})
+	}
+}
+
+func TestCompileVariableValidation(t *testing.T) {
+	validation, err := CompileVariableValidation(`${self != ""}`, "must not be empty")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if ok, err := validation.Check("foo"); err != nil || !ok {
+		t.Fatalf("expected condition to pass, got ok=%v err=%v", ok, err)
+	}
+	if ok, err := validation.Check(""); err != nil || ok {
+		t.Fatalf("expected condition to fail, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestCompileVariableValidation_parseError(t *testing.T) {
+	if _, err := CompileVariableValidation(`${`, "bad"); err == nil {
+		t.Fatalf("expected a parse error for a malformed condition")
+	}
+}
+
+func TestVariableValidation_check(t *testing.T) {
+	v := &VariableValidation{
+		Condition: func(self interface{}) (bool, error) {
+			s, ok := self.(string)
+			return ok && len(s) > 0, nil
+		},
+		ErrorMessage: "must not be empty",
+	}
+
+	if ok, err := v.Check("foo"); err != nil || !ok {
+		t.Fatalf("expected condition to pass, got ok=%v err=%v", ok, err)
+	}
+	if ok, err := v.Check(""); err != nil || ok {
+		t.Fatalf("expected condition to fail, got ok=%v err=%v", ok, err)
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hil"
+	"github.com/hashicorp/hil/ast"
+)
+
+// CompileVariableValidation compiles a validation block's `condition`
+// expression, e.g. `"${var.self != \"\"}"`, into a VariableValidation
+// through the same HIL interpolation engine the rest of the config
+// package uses, so that var.self resolves to the variable's proposed
+// value the way any other interpolated reference would rather than
+// requiring a hand-written Go closure.
+//
+// The scope this binds only ever contains self/var.self; a validation
+// condition is evaluated in isolation from the rest of the interpolation
+// graph, so it can't reference other variables or resources. It also
+// carries no function table, so a condition that calls a function such
+// as length(...) fails to evaluate.
+func CompileVariableValidation(condition, errorMessage string) (*VariableValidation, error) {
+	root, err := hil.Parse(condition)
+	if err != nil {
+		return nil, fmt.Errorf("parsing condition: %s", err)
+	}
+
+	return &VariableValidation{
+		Condition: func(self interface{}) (bool, error) {
+			selfVar, err := hilVariable(self)
+			if err != nil {
+				return false, err
+			}
+
+			result, resultType, err := hil.Eval(root, &hil.EvalConfig{
+				GlobalScope: &ast.BasicScope{
+					VarMap: map[string]ast.Variable{
+						"self":     selfVar,
+						"var.self": selfVar,
+					},
+				},
+			})
+			if err != nil {
+				return false, fmt.Errorf("evaluating condition: %s", err)
+			}
+
+			switch resultType {
+			case ast.TypeBool:
+				return result.(bool), nil
+			case ast.TypeString:
+				return result.(string) != "" && result.(string) != "false", nil
+			default:
+				return false, fmt.Errorf("condition must evaluate to a bool, got %s", resultType)
+			}
+		},
+		ErrorMessage: errorMessage,
+	}, nil
+}
+
+// hilVariable converts a variable's proposed Go value into the
+// ast.Variable representation HIL needs to bind it into an evaluation
+// scope.
+func hilVariable(v interface{}) (ast.Variable, error) {
+	switch v := v.(type) {
+	case string:
+		return ast.Variable{Type: ast.TypeString, Value: v}, nil
+	case bool:
+		return ast.Variable{Type: ast.TypeBool, Value: v}, nil
+	case int:
+		return ast.Variable{Type: ast.TypeInt, Value: v}, nil
+	case int64:
+		return ast.Variable{Type: ast.TypeInt, Value: int(v)}, nil
+	case float64:
+		return ast.Variable{Type: ast.TypeFloat, Value: v}, nil
+	case []interface{}:
+		elems := make([]ast.Variable, len(v))
+		for i, e := range v {
+			elemVar, err := hilVariable(e)
+			if err != nil {
+				return ast.Variable{}, err
+			}
+			elems[i] = elemVar
+		}
+		return ast.Variable{Type: ast.TypeList, Value: elems}, nil
+	case map[string]interface{}:
+		elems := make(map[string]ast.Variable, len(v))
+		for k, e := range v {
+			elemVar, err := hilVariable(e)
+			if err != nil {
+				return ast.Variable{}, err
+			}
+			elems[k] = elemVar
+		}
+		return ast.Variable{Type: ast.TypeMap, Value: elems}, nil
+	default:
+		return ast.Variable{}, fmt.Errorf("cannot bind value of type %T into a validation condition", v)
+	}
+}
@@ -0,0 +1,94 @@
+package terraform
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is how serious a Diagnostic is: an error that must stop the
+// run, or a warning that shouldn't.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+// Diagnostic is a structured problem report, used in place of an ad-hoc
+// fmt.Errorf string wherever a caller needs more than just a message.
+//
+// There's deliberately no source position here yet: nothing in this tree
+// parses HCL into a node that could supply one, and a Pos field nothing
+// ever populates is worse than no field at all. Add it back once a
+// caller can actually set it.
+type Diagnostic struct {
+	Severity   Severity
+	Summary    string
+	Detail     string
+	ModulePath []string
+}
+
+func (d Diagnostic) Error() string {
+	if d.Detail == "" {
+		return d.Summary
+	}
+	return fmt.Sprintf("%s: %s", d.Summary, d.Detail)
+}
+
+// Diagnostics is an accumulated collection of Diagnostic values, used so
+// that an EvalNode which can encounter more than one problem in a single
+// pass (EvalTypeCheckVariable checking every variable in a module, say)
+// can report all of them instead of bailing out on the first.
+type Diagnostics []Diagnostic
+
+func (d *Diagnostics) Append(diags ...Diagnostic) {
+	*d = append(*d, diags...)
+}
+
+func (d Diagnostics) HasErrors() bool {
+	for _, diag := range d {
+		if diag.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns d as an error, or nil if it's empty.
+func (d Diagnostics) Err() error {
+	if len(d) == 0 {
+		return nil
+	}
+	return d
+}
+
+func (d Diagnostics) Error() string {
+	switch len(d) {
+	case 0:
+		return ""
+	case 1:
+		return d[0].Error()
+	default:
+		msgs := make([]string, len(d))
+		for i, diag := range d {
+			msgs[i] = diag.Error()
+		}
+		return strings.Join(msgs, "\n")
+	}
+}
+
+// Format renders d the way the UI layer presents diagnostics to a user:
+// one paragraph per diagnostic, prefixed with its severity.
+func (d Diagnostics) Format() string {
+	paragraphs := make([]string, len(d))
+	for i, diag := range d {
+		prefix := "Error"
+		if diag.Severity == SeverityWarning {
+			prefix = "Warning"
+		}
+
+		paragraphs[i] = fmt.Sprintf("%s: %s", prefix, diag.Error())
+	}
+
+	return strings.Join(paragraphs, "\n\n")
+}
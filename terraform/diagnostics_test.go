@@ -0,0 +1,35 @@
+package terraform
+
+import "testing"
+
+func TestDiagnostics_errAndHasErrors(t *testing.T) {
+	var diags Diagnostics
+	if diags.Err() != nil {
+		t.Fatalf("empty Diagnostics should have a nil Err()")
+	}
+	if diags.HasErrors() {
+		t.Fatalf("empty Diagnostics should not HasErrors()")
+	}
+
+	diags.Append(Diagnostic{Severity: SeverityWarning, Summary: "just a warning"})
+	if diags.HasErrors() {
+		t.Fatalf("a warning-only Diagnostics should not HasErrors()")
+	}
+
+	diags.Append(Diagnostic{Severity: SeverityError, Summary: "boom"})
+	if !diags.HasErrors() {
+		t.Fatalf("expected HasErrors() once an error diagnostic is appended")
+	}
+	if diags.Err() == nil {
+		t.Fatalf("expected a non-nil Err() once diagnostics are appended")
+	}
+}
+
+func TestBuiltinEvalContext_diagnostics(t *testing.T) {
+	ctx := &BuiltinEvalContext{}
+	ctx.Diagnostics().Append(Diagnostic{Severity: SeverityError, Summary: "bad"})
+
+	if !ctx.Diagnostics().HasErrors() {
+		t.Fatalf("expected the diagnostic appended via the sink to stick")
+	}
+}
@@ -0,0 +1,33 @@
+package terraform
+
+// EvalContext is given to every EvalNode's Eval call, providing access to
+// the state that's shared across a single graph walk.
+type EvalContext interface {
+	// SetVariables sets the variables for the module with the given name,
+	// making them available for interpolation within that module.
+	SetVariables(n string, vs map[string]interface{})
+
+	// Diagnostics returns the sink that EvalNodes append structured
+	// problem reports to while they run. Nothing in this package reads
+	// the sink back or prints it; Diagnostics.Format renders its contents
+	// for whoever does.
+	Diagnostics() *Diagnostics
+}
+
+// BuiltinEvalContext is the main EvalContext implementation used by a
+// real Terraform graph walk.
+type BuiltinEvalContext struct {
+	Variables map[string]map[string]interface{}
+	diags     Diagnostics
+}
+
+func (ctx *BuiltinEvalContext) SetVariables(n string, vs map[string]interface{}) {
+	if ctx.Variables == nil {
+		ctx.Variables = make(map[string]map[string]interface{})
+	}
+	ctx.Variables[n] = vs
+}
+
+func (ctx *BuiltinEvalContext) Diagnostics() *Diagnostics {
+	return &ctx.diags
+}
@@ -24,9 +24,14 @@ import (
 //     - the path to the module (so we know which part of the tree to
 //       compare the values against).
 //
-// Currently since the type system is simple, we currently do not make
-// use of the values since it is only valid to pass string values. The
-// structure is in place for extension of the type system, however.
+// A variable's type is a full config.TypeSpec, not just the outer Go kind:
+// a `type = list(object({name=string, port=number}))` declaration names
+// the element type of the list and the type of each attribute of the
+// object nested inside it, and checkVariableType validates and coerces
+// the proposed value against that whole structure, recursing into list
+// elements, map elements, object attributes and tuple positions as it
+// goes. A value with no explicit `type = ...` infers its TypeSpec from
+// the variable's default instead.
 type EvalTypeCheckVariable struct {
 	Variables  map[string]interface{}
 	ModulePath []string
@@ -40,23 +45,23 @@ func (n *EvalTypeCheckVariable) Eval(ctx EvalContext) (interface{}, error) {
 	}
 	targetConfig := currentTree.Config()
 
-	prototypes := make(map[string]config.VariableType)
-	for _, variable := range targetConfig.Variables {
-		prototypes[variable.Name] = variable.Type()
-	}
-
 	// Only display a module in an error message if we are not in the root module
 	modulePathDescription := fmt.Sprintf(" in module %s", strings.Join(n.ModulePath[1:], "."))
 	if len(n.ModulePath) == 1 {
 		modulePathDescription = ""
 	}
 
-	for name, declaredType := range prototypes {
-		// This is only necessary when we _actually_ check. It is left as a reminder
-		// that at the current time we are dealing with a type system consisting only
-		// of strings and maps - where the only valid inter-module variable type is
-		// string.
-		proposedValue, ok := n.Variables[name]
+	var diags Diagnostics
+
+	// failed tracks which variables didn't survive type checking, so the
+	// validation pass below can skip them: a validation condition is
+	// written assuming the variable already has its declared type, and
+	// running one against a value that failed type checking risks a
+	// type-assertion panic instead of a clean diagnostic.
+	failed := make(map[string]bool)
+
+	for _, variable := range targetConfig.Variables {
+		proposedValue, ok := n.Variables[variable.Name]
 		if !ok {
 			// This means the default value should be used as no overriding value
 			// has been set. Therefore we should continue as no check is necessary.
@@ -67,42 +72,241 @@ func (n *EvalTypeCheckVariable) Eval(ctx EvalContext) (interface{}, error) {
 			continue
 		}
 
-		switch declaredType {
-		case config.VariableTypeString:
-			// This will need actual verification once we aren't dealing with
-			// a map[string]string but this is sufficient for now.
-			switch proposedValue.(type) {
-			case string:
+		spec, err := variable.TypeSpec()
+		if err != nil {
+			failed[variable.Name] = true
+			diags.Append(Diagnostic{
+				Severity:   SeverityError,
+				Summary:    fmt.Sprintf("Invalid type for variable %q%s", variable.Name, modulePathDescription),
+				Detail:     err.Error(),
+				ModulePath: n.ModulePath,
+			})
+			continue
+		}
+
+		coerced, err := checkVariableType(proposedValue, spec)
+		if err != nil {
+			failed[variable.Name] = true
+			diags.Append(Diagnostic{
+				Severity:   SeverityError,
+				Summary:    fmt.Sprintf("Invalid value for variable %q%s", variable.Name, modulePathDescription),
+				Detail:     err.Error(),
+				ModulePath: n.ModulePath,
+			})
+			continue
+		}
+		n.Variables[variable.Name] = coerced
+	}
+
+	for _, variable := range targetConfig.Variables {
+		if len(variable.Validations) == 0 || failed[variable.Name] {
+			continue
+		}
+
+		proposedValue, ok := n.Variables[variable.Name]
+		if !ok || proposedValue == config.UnknownVariableValue {
+			continue
+		}
+		if sv, ok := proposedValue.(SensitiveVariableValue); ok {
+			proposedValue = sv.Value
+		}
+
+		for _, validation := range variable.Validations {
+			// Check evaluates the condition expression with var.self bound
+			// to the proposed value; it does not need the wider
+			// interpolation scope since a validation rule may only refer
+			// to the variable it is attached to.
+			ok, err := validation.Check(proposedValue)
+			if err != nil {
+				diags.Append(Diagnostic{
+					Severity:   SeverityError,
+					Summary:    fmt.Sprintf("Invalid validation condition for variable %q%s", variable.Name, modulePathDescription),
+					Detail:     err.Error(),
+					ModulePath: n.ModulePath,
+				})
+				continue
+			}
+			if !ok {
+				diags.Append(Diagnostic{
+					Severity:   SeverityError,
+					Summary:    fmt.Sprintf("Invalid value for variable %q%s", variable.Name, modulePathDescription),
+					Detail:     validation.ErrorMessage,
+					ModulePath: n.ModulePath,
+				})
+			}
+		}
+	}
+
+	if len(diags) > 0 {
+		ctx.Diagnostics().Append(diags...)
+	}
+	if diags.HasErrors() {
+		return nil, diags.Err()
+	}
+
+	return nil, nil
+}
+
+// checkVariableType verifies that proposedValue conforms to spec, recursing
+// into list/map elements, object attributes and tuple positions so that
+// nested structure is validated rather than just the outer Go kind, and
+// returns the value to actually use going forward. Coercion happens where
+// HCL's own ambiguity calls for it: a bare bool/int/float is coerced to
+// its string form for a string-typed variable, and a numeric string is
+// parsed for a number-typed one, since anything downstream that
+// type-asserts the result is entitled to assume it got the declared kind.
+func checkVariableType(proposedValue interface{}, spec *config.TypeSpec) (interface{}, error) {
+	if sv, ok := proposedValue.(SensitiveVariableValue); ok {
+		coerced, err := checkVariableType(sv.Value, spec)
+		if err != nil {
+			return nil, err
+		}
+		return SensitiveVariableValue{Value: coerced}, nil
+	}
+
+	if proposedValue == nil {
+		if spec == nil || spec.Nullable || spec.Kind == config.KindAny {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("should be type %s, got null", spec.String())
+	}
+
+	if spec == nil || spec.Kind == config.KindAny {
+		return proposedValue, nil
+	}
+
+	switch spec.Kind {
+	case config.KindString:
+		switch v := proposedValue.(type) {
+		case string:
+			return v, nil
+		case bool, int, int64, float64:
+			return fmt.Sprintf("%v", v), nil
+		default:
+			return nil, fmt.Errorf("should be type %s, got %s", spec.String(), hclTypeName(proposedValue))
+		}
+	case config.KindBool:
+		switch v := proposedValue.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("should be type %s, got string %q", spec.String(), v)
+			}
+			return b, nil
+		default:
+			return nil, fmt.Errorf("should be type %s, got %s", spec.String(), hclTypeName(proposedValue))
+		}
+	case config.KindNumber:
+		switch v := proposedValue.(type) {
+		case int, int64, float64:
+			return v, nil
+		case string:
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("should be type %s, got string %q", spec.String(), v)
+			}
+			return n, nil
+		default:
+			return nil, fmt.Errorf("should be type %s, got %s", spec.String(), hclTypeName(proposedValue))
+		}
+	case config.KindList:
+		vSlice, ok := proposedValue.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("should be type %s, got %s", spec.String(), hclTypeName(proposedValue))
+		}
+		result := make([]interface{}, len(vSlice))
+		for i, v := range vSlice {
+			if v == config.UnknownVariableValue {
+				result[i] = v
+				continue
+			}
+			coerced, err := checkVariableType(v, spec.Element)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %s", i, err)
+			}
+			result[i] = coerced
+		}
+		return result, nil
+	case config.KindMap:
+		vMap, ok := proposedValue.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("should be type %s, got %s", spec.String(), hclTypeName(proposedValue))
+		}
+		result := make(map[string]interface{}, len(vMap))
+		for k, v := range vMap {
+			if v == config.UnknownVariableValue {
+				result[k] = v
 				continue
-			default:
-				return nil, fmt.Errorf("variable %s%s should be type %s, got %s",
-					name, modulePathDescription, declaredType.Printable(), hclTypeName(proposedValue))
 			}
-		case config.VariableTypeMap:
-			switch proposedValue.(type) {
-			case map[string]interface{}:
+			coerced, err := checkVariableType(v, spec.Element)
+			if err != nil {
+				return nil, fmt.Errorf("element %q: %s", k, err)
+			}
+			result[k] = coerced
+		}
+		return result, nil
+	case config.KindObject:
+		vMap, ok := proposedValue.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("should be type %s, got %s", spec.String(), hclTypeName(proposedValue))
+		}
+		result := make(map[string]interface{}, len(spec.Attributes))
+		for name, attrSpec := range spec.Attributes {
+			v, ok := vMap[name]
+			if !ok {
+				if attrSpec.Nullable {
+					continue
+				}
+				return nil, fmt.Errorf("missing required attribute %q for %s", name, spec.String())
+			}
+			if v == config.UnknownVariableValue {
+				result[name] = v
 				continue
-			default:
-				return nil, fmt.Errorf("variable %s%s should be type %s, got %s",
-					name, modulePathDescription, declaredType.Printable(), hclTypeName(proposedValue))
 			}
-		case config.VariableTypeList:
-			switch proposedValue.(type) {
-			case []interface{}:
+			coerced, err := checkVariableType(v, attrSpec)
+			if err != nil {
+				return nil, fmt.Errorf("attribute %q: %s", name, err)
+			}
+			result[name] = coerced
+		}
+		return result, nil
+	case config.KindTuple:
+		vSlice, ok := proposedValue.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("should be type %s, got %s", spec.String(), hclTypeName(proposedValue))
+		}
+		if len(vSlice) != len(spec.Elements) {
+			return nil, fmt.Errorf("should be type %s with %d elements, got %d", spec.String(), len(spec.Elements), len(vSlice))
+		}
+		result := make([]interface{}, len(vSlice))
+		for i, v := range vSlice {
+			if v == config.UnknownVariableValue {
+				result[i] = v
 				continue
-			default:
-				return nil, fmt.Errorf("variable %s%s should be type %s, got %s",
-					name, modulePathDescription, declaredType.Printable(), hclTypeName(proposedValue))
 			}
-		default:
-			// This will need the actual type substituting when we have more than
-			// just strings and maps.
-			return nil, fmt.Errorf("variable %s%s should be type %s, got type string",
-				name, modulePathDescription, declaredType.Printable())
+			coerced, err := checkVariableType(v, spec.Elements[i])
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %s", i, err)
+			}
+			result[i] = coerced
 		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("should be type %s", spec.String())
 	}
+}
 
-	return nil, nil
+// SensitiveVariableValue wraps the value of a variable declared
+// `sensitive = true`, so that log lines, plan output and state diffs
+// redact it instead of printing it verbatim.
+type SensitiveVariableValue struct {
+	Value interface{}
+}
+
+func (v SensitiveVariableValue) String() string {
+	return "<sensitive>"
 }
 
 // EvalSetVariables is an EvalNode implementation that sets the variables
@@ -110,20 +314,66 @@ func (n *EvalTypeCheckVariable) Eval(ctx EvalContext) (interface{}, error) {
 type EvalSetVariables struct {
 	Module    *string
 	Variables map[string]interface{}
+
+	// Sources, if given, takes precedence over Variables: the final
+	// value for each declared variable is resolved by collapsing every
+	// source in order (literal HCL, TF_VAR_* environment variables,
+	// tfvars files, a remote provider, ...) before the result is handed
+	// to EvalTypeCheckVariable. Variables is still what ends up set on
+	// the context either way. The set of names to resolve comes from
+	// VariableNames if given, otherwise every variable declared in
+	// ModuleTree's config at ModulePath.
+	Sources       VariableSources
+	VariableNames []string
+	ModulePath    []string
+	ModuleTree    *module.Tree
 }
 
 // TODO: test
 func (n *EvalSetVariables) Eval(ctx EvalContext) (interface{}, error) {
+	if n.Sources != nil {
+		names := n.VariableNames
+		if names == nil {
+			names = resolveVariableNames(n.declaredVariables())
+		}
+
+		resolved, err := n.Sources.Resolve(names)
+		if err != nil {
+			return nil, err
+		}
+		n.Variables = resolved
+	}
+
 	ctx.SetVariables(*n.Module, n.Variables)
 	return nil, nil
 }
 
+// declaredVariables looks up the variables declared at ModulePath within
+// ModuleTree, which is the universe Sources needs to resolve values for
+// when VariableNames wasn't given explicitly.
+func (n *EvalSetVariables) declaredVariables() []*config.Variable {
+	if n.ModuleTree == nil {
+		return nil
+	}
+
+	currentTree := n.ModuleTree
+	for _, pathComponent := range n.ModulePath[1:] {
+		currentTree = currentTree.Children()[pathComponent]
+	}
+
+	return currentTree.Config().Variables
+}
+
 // EvalVariableBlock is an EvalNode implementation that evaluates the
 // given configuration, and uses the final values as a way to set the
 // mapping.
 type EvalVariableBlock struct {
 	Config         **ResourceConfig
 	VariableValues map[string]interface{}
+
+	// Sensitive lists the names of variables declared `sensitive = true`
+	// on the receiving module.
+	Sensitive map[string]bool
 }
 
 // TODO: test
@@ -134,27 +384,32 @@ func (n *EvalVariableBlock) Eval(ctx EvalContext) (interface{}, error) {
 	}
 
 	// Get our configuration
+	var diags Diagnostics
 	rc := *n.Config
 	for k, v := range rc.Config {
 		var vString string
 		if err := hilmapstructure.WeakDecode(v, &vString); err == nil {
-			n.VariableValues[k] = vString
+			n.VariableValues[k] = n.taintSensitive(k, vString)
 			continue
 		}
 
 		var vMap map[string]interface{}
 		if err := hilmapstructure.WeakDecode(v, &vMap); err == nil {
-			n.VariableValues[k] = vMap
+			n.VariableValues[k] = n.taintSensitive(k, vMap)
 			continue
 		}
 
 		var vSlice []interface{}
 		if err := hilmapstructure.WeakDecode(v, &vSlice); err == nil {
-			n.VariableValues[k] = vSlice
+			n.VariableValues[k] = n.taintSensitive(k, vSlice)
 			continue
 		}
 
-		return nil, fmt.Errorf("Variable value for %s is not a string, list or map type", k)
+		diags.Append(Diagnostic{
+			Severity: SeverityError,
+			Summary:  fmt.Sprintf("Invalid value for variable %q", k),
+			Detail:   "value is not a string, list or map type",
+		})
 	}
 
 	for _, path := range rc.ComputedKeys {
@@ -165,9 +420,25 @@ func (n *EvalVariableBlock) Eval(ctx EvalContext) (interface{}, error) {
 		}
 	}
 
+	if len(diags) > 0 {
+		ctx.Diagnostics().Append(diags...)
+	}
+	if diags.HasErrors() {
+		return nil, diags.Err()
+	}
+
 	return nil, nil
 }
 
+// taintSensitive wraps v in a SensitiveVariableValue when name was declared
+// sensitive on the receiving module, otherwise it returns v unchanged.
+func (n *EvalVariableBlock) taintSensitive(name string, v interface{}) interface{} {
+	if !n.Sensitive[name] {
+		return v
+	}
+	return SensitiveVariableValue{Value: v}
+}
+
 func (n *EvalVariableBlock) setUnknownVariableValueForPath(path string) error {
 	pathComponents := strings.Split(path, ".")
 
@@ -0,0 +1,192 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/config/module"
+)
+
+func TestCheckVariableType_string(t *testing.T) {
+	spec := &config.TypeSpec{Kind: config.KindString}
+
+	cases := []struct {
+		Name  string
+		Value interface{}
+		Want  interface{}
+		Err   bool
+	}{
+		{"string passes through", "foo", "foo", false},
+		{"bool is coerced to string", true, "true", false},
+		{"int is coerced to string", 42, "42", false},
+		{"float is coerced to string", 1.5, "1.5", false},
+		{"map is rejected", map[string]interface{}{"a": "b"}, nil, true},
+		{"list is rejected", []interface{}{"a"}, nil, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			got, err := checkVariableType(tc.Value, spec)
+			if tc.Err {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(got, tc.Want) {
+				t.Fatalf("got %#v, want %#v", got, tc.Want)
+			}
+			if _, ok := got.(string); !ok {
+				t.Fatalf("coerced value %#v is not a string", got)
+			}
+		})
+	}
+}
+
+func TestCheckVariableType_bool(t *testing.T) {
+	spec := &config.TypeSpec{Kind: config.KindBool}
+
+	if got, err := checkVariableType("true", spec); err != nil || got != true {
+		t.Fatalf("expected string \"true\" to coerce to bool true, got %#v err=%v", got, err)
+	}
+	if _, err := checkVariableType("nope", spec); err == nil {
+		t.Fatalf("expected an error for a non-boolean string")
+	}
+	if _, err := checkVariableType(42, spec); err == nil {
+		t.Fatalf("expected an error for a number")
+	}
+}
+
+func TestCheckVariableType_number(t *testing.T) {
+	spec := &config.TypeSpec{Kind: config.KindNumber}
+
+	if got, err := checkVariableType("42", spec); err != nil || got != float64(42) {
+		t.Fatalf("expected string \"42\" to coerce to number 42, got %#v err=%v", got, err)
+	}
+	if _, err := checkVariableType("nope", spec); err == nil {
+		t.Fatalf("expected an error for a non-numeric string")
+	}
+}
+
+func TestCheckVariableType_nestedList(t *testing.T) {
+	spec := &config.TypeSpec{Kind: config.KindList, Element: &config.TypeSpec{Kind: config.KindAny}}
+
+	value := []interface{}{
+		"a",
+		42,
+		[]interface{}{"nested", true},
+		map[string]interface{}{"k": "v"},
+	}
+
+	if _, err := checkVariableType(value, spec); err != nil {
+		t.Fatalf("unexpected error for well-formed nested list: %s", err)
+	}
+}
+
+func TestCheckVariableType_listOfNumber(t *testing.T) {
+	spec := &config.TypeSpec{Kind: config.KindList, Element: &config.TypeSpec{Kind: config.KindNumber}}
+
+	got, err := checkVariableType([]interface{}{"1", 2}, spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := checkVariableType([]interface{}{"not-a-number"}, spec); err == nil {
+		t.Fatalf("expected an error for a non-numeric element")
+	}
+	if len(got.([]interface{})) != 2 {
+		t.Fatalf("expected 2 elements, got %#v", got)
+	}
+}
+
+func TestCheckVariableType_object(t *testing.T) {
+	spec := &config.TypeSpec{
+		Kind: config.KindObject,
+		Attributes: map[string]*config.TypeSpec{
+			"name": {Kind: config.KindString},
+			"port": {Kind: config.KindNumber},
+		},
+	}
+
+	got, err := checkVariableType(map[string]interface{}{"name": "web", "port": "8080"}, spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	m := got.(map[string]interface{})
+	if m["name"] != "web" || m["port"] != float64(8080) {
+		t.Fatalf("got %#v", m)
+	}
+
+	if _, err := checkVariableType(map[string]interface{}{"name": "web"}, spec); err == nil {
+		t.Fatalf("expected an error for a missing required attribute")
+	}
+}
+
+func TestCheckVariableType_nullability(t *testing.T) {
+	required := &config.TypeSpec{Kind: config.KindString}
+	if _, err := checkVariableType(nil, required); err == nil {
+		t.Fatalf("expected an error for null against a non-nullable type")
+	}
+
+	nullable := &config.TypeSpec{Kind: config.KindString, Nullable: true}
+	got, err := checkVariableType(nil, nullable)
+	if err != nil || got != nil {
+		t.Fatalf("expected nil to pass through for a nullable type, got %#v err=%v", got, err)
+	}
+}
+
+// TestEvalTypeCheckVariable_skipsValidationAfterFailedTypeCheck guards
+// against a validation condition panicking on a value that never had the
+// declared type in the first place: the variable here fails type
+// checking (a map where a string was declared), and its Condition would
+// panic on anything but a string if it ran.
+func TestEvalTypeCheckVariable_skipsValidationAfterFailedTypeCheck(t *testing.T) {
+	v := &config.Variable{
+		Name:         "name",
+		DeclaredType: "string",
+		Validations: []config.VariableValidation{
+			{
+				Condition: func(self interface{}) (bool, error) {
+					return len(self.(string)) > 0, nil
+				},
+				ErrorMessage: "must not be empty",
+			},
+		},
+	}
+	tree := module.NewTree(&config.Config{Variables: []*config.Variable{v}}, nil)
+
+	n := &EvalTypeCheckVariable{
+		Variables:  map[string]interface{}{"name": map[string]interface{}{"a": "b"}},
+		ModulePath: []string{"root"},
+		ModuleTree: tree,
+	}
+
+	ctx := &BuiltinEvalContext{}
+	if _, err := n.Eval(ctx); err == nil {
+		t.Fatalf("expected a type-check error")
+	}
+
+	if !ctx.Diagnostics().HasErrors() {
+		t.Fatalf("expected the type-check failure to surface as a diagnostic")
+	}
+}
+
+func TestCheckVariableType_sensitiveValuePreserved(t *testing.T) {
+	value := SensitiveVariableValue{Value: true}
+
+	got, err := checkVariableType(value, &config.TypeSpec{Kind: config.KindString})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sv, ok := got.(SensitiveVariableValue)
+	if !ok {
+		t.Fatalf("expected a SensitiveVariableValue, got %#v", got)
+	}
+	if sv.Value != "true" {
+		t.Fatalf("expected coerced inner value %q, got %#v", "true", sv.Value)
+	}
+}
@@ -0,0 +1,297 @@
+package terraform
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform/config"
+)
+
+// variableSourcePrefix is the prefix Terraform has always recognized on
+// environment variables used to supply input variable values, e.g.
+// TF_VAR_foo sets variable "foo".
+const variableSourcePrefix = "TF_VAR_"
+
+// VariableSource resolves values for declared variables from a single
+// origin: literal HCL assignment, `TF_VAR_*` environment variables, a
+// parsed .tfvars/.tfvars.json file, an interactive prompt, or a pluggable
+// remote provider such as a secret store. EvalSetVariables consults an
+// ordered list of these, through its Sources field, instead of having
+// each origin's precedence wired in by hand; EvalVariableBlock does not
+// consult sources.
+type VariableSource interface {
+	// Name identifies the source for error messages, e.g. "environment"
+	// or "-var-file=foo.tfvars".
+	Name() string
+
+	// VariableValue looks up a single variable by name. ok is false if
+	// this source has no opinion about the variable, in which case
+	// resolution falls through to the next source in precedence order.
+	VariableValue(name string) (value interface{}, ok bool, err error)
+}
+
+// VariableSources is an ordered list of VariableSource, lowest precedence
+// first. This mirrors the order Terraform has always documented for
+// variable inputs: environment variables, then *.auto.tfvars files, then
+// explicit -var-file arguments, then -var arguments, with any interactive
+// prompt only consulted once every other source has had a chance.
+type VariableSources []VariableSource
+
+// Resolve collapses every source into a single map of values for the
+// given variable names. Only the requested names are looked up, so a
+// remote source is never queried for a name outside names; whether a
+// requested name is referenced anywhere is not checked (EvalSetVariables
+// with no VariableNames requests every declared variable). Later
+// sources take precedence over earlier ones; map values are deep-merged
+// rather than replaced outright, since an override is usually meant to
+// patch a few keys (at any depth) rather than discard the whole map. List
+// values are replaced wholesale - there's no well-defined way to merge two
+// ordered sequences element-by-element.
+func (s VariableSources) Resolve(names []string) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+
+	for _, name := range names {
+		for _, source := range s {
+			value, ok, err := source.VariableValue(name)
+			if err != nil {
+				return nil, fmt.Errorf("resolving variable %q from %s: %s", name, source.Name(), err)
+			}
+			if !ok {
+				continue
+			}
+
+			if existing, hasExisting := result[name]; hasExisting {
+				result[name] = mergeVariableValue(existing, value)
+				continue
+			}
+
+			result[name] = value
+		}
+	}
+
+	return result, nil
+}
+
+// mergeVariableValue combines a lower-precedence value with a
+// higher-precedence override. When both are maps, they're merged
+// recursively key by key so that an override map only has to mention the
+// keys it's actually changing; any other value is replaced outright.
+func mergeVariableValue(existing, override interface{}) interface{} {
+	existingMap, existingIsMap := existing.(map[string]interface{})
+	overrideMap, overrideIsMap := override.(map[string]interface{})
+	if !existingIsMap || !overrideIsMap {
+		return override
+	}
+
+	merged := make(map[string]interface{}, len(existingMap))
+	for k, v := range existingMap {
+		merged[k] = v
+	}
+	for k, v := range overrideMap {
+		if existingValue, ok := merged[k]; ok {
+			merged[k] = mergeVariableValue(existingValue, v)
+			continue
+		}
+		merged[k] = v
+	}
+
+	return merged
+}
+
+// LiteralVariableSource is a VariableSource backed by an already-resolved
+// map of values, used for values that arrive pre-parsed: HCL variable
+// blocks' defaults, -var command line arguments, and parsed
+// .tfvars/.tfvars.json files all reduce to this by the time they reach
+// the graph.
+type LiteralVariableSource struct {
+	SourceName string
+	Values     map[string]interface{}
+}
+
+func (s *LiteralVariableSource) Name() string {
+	return s.SourceName
+}
+
+func (s *LiteralVariableSource) VariableValue(name string) (interface{}, bool, error) {
+	v, ok := s.Values[name]
+	return v, ok, nil
+}
+
+// EnvVariableSource resolves variable values from TF_VAR_-prefixed
+// environment variables. Environment values are always strings, matching
+// the long-standing CLI behavior of treating TF_VAR_foo as though "foo"
+// had been passed with -var.
+type EnvVariableSource struct {
+	// Environ is the process environment to read, in os.Environ() form.
+	// Tests can supply a fixed slice instead of reading the real
+	// environment.
+	Environ []string
+}
+
+func (s *EnvVariableSource) Name() string {
+	return "environment"
+}
+
+func (s *EnvVariableSource) VariableValue(name string) (interface{}, bool, error) {
+	environ := s.Environ
+	if environ == nil {
+		environ = os.Environ()
+	}
+
+	key := variableSourcePrefix + name
+	for _, kv := range environ {
+		eq := strings.IndexByte(kv, '=')
+		if eq < 0 {
+			continue
+		}
+		if kv[:eq] == key {
+			return kv[eq+1:], true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// FileVariableSource is a VariableSource backed by a parsed .tfvars or
+// .tfvars.json file. Parsing the file itself is the CLI layer's job (HCL
+// for .tfvars, encoding/json for .tfvars.json); by the time a
+// FileVariableSource exists it's just holding the resulting values, the
+// same as LiteralVariableSource, but keeping its own type lets error
+// messages and -var-file precedence rules refer to "the tfvars file" by
+// name rather than by a generic "literal" label.
+type FileVariableSource struct {
+	Path   string
+	Values map[string]interface{}
+}
+
+func (s *FileVariableSource) Name() string {
+	return s.Path
+}
+
+func (s *FileVariableSource) VariableValue(name string) (interface{}, bool, error) {
+	v, ok := s.Values[name]
+	return v, ok, nil
+}
+
+// StdinVariableSource prompts, through Prompt or through the given
+// Input/Output streams (typically stdin/stdout), for any variable it's asked
+// about that isn't already in Answers, caching the answer so a variable
+// referenced from more than one module is only asked about once. Prompt,
+// when set, takes precedence over Input/Output - that's the hook tests
+// and alternate UIs use to avoid touching a real terminal. With Prompt
+// unset, VariableValue falls back to writing the prompt text to Output
+// and reading a line from Input directly. With neither Prompt nor Input
+// set it reports no value; it never opens os.Stdin on its own.
+type StdinVariableSource struct {
+	Prompt func(name string) (string, error)
+	Input  io.Reader
+	Output io.Writer
+
+	Answers map[string]string
+}
+
+func (s *StdinVariableSource) Name() string {
+	return "console"
+}
+
+func (s *StdinVariableSource) VariableValue(name string) (interface{}, bool, error) {
+	if v, ok := s.Answers[name]; ok {
+		return v, true, nil
+	}
+
+	var answer string
+	var err error
+	switch {
+	case s.Prompt != nil:
+		answer, err = s.Prompt(name)
+	case s.Input != nil:
+		answer, err = s.readLine(name)
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if s.Answers == nil {
+		s.Answers = make(map[string]string)
+	}
+	s.Answers[name] = answer
+
+	return answer, true, nil
+}
+
+// readLine is the fallback used when no Prompt func is given: it writes
+// the prompt text to Output, if given, then reads a single line from
+// Input.
+func (s *StdinVariableSource) readLine(name string) (string, error) {
+	if s.Output != nil {
+		fmt.Fprintf(s.Output, "var.%s\n  Enter a value: ", name)
+	}
+
+	line, err := bufio.NewReader(s.Input).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// RemoteVariableSource is implemented by pluggable providers that fetch
+// variable values from an external system, such as an HTTP or gRPC call
+// to a secret store. It's deliberately narrower than VariableSource so
+// that provider authors only need to implement a lookup, not precedence
+// or naming.
+type RemoteVariableSource interface {
+	FetchVariable(name string) (value interface{}, ok bool, err error)
+}
+
+// CachingRemoteVariableSource wraps a RemoteVariableSource so that each
+// variable is only ever fetched once per run, no matter how many graph
+// nodes ask for it.
+type CachingRemoteVariableSource struct {
+	SourceName string
+	Remote     RemoteVariableSource
+
+	once  sync.Map // name -> *sync.Once
+	cache sync.Map // name -> cachedVariableValue
+}
+
+type cachedVariableValue struct {
+	value interface{}
+	ok    bool
+	err   error
+}
+
+func (s *CachingRemoteVariableSource) Name() string {
+	return s.SourceName
+}
+
+func (s *CachingRemoteVariableSource) VariableValue(name string) (interface{}, bool, error) {
+	onceIface, _ := s.once.LoadOrStore(name, &sync.Once{})
+	once := onceIface.(*sync.Once)
+
+	once.Do(func() {
+		value, ok, err := s.Remote.FetchVariable(name)
+		s.cache.Store(name, cachedVariableValue{value: value, ok: ok, err: err})
+	})
+
+	cached, _ := s.cache.Load(name)
+	result := cached.(cachedVariableValue)
+	return result.value, result.ok, result.err
+}
+
+// resolveVariableNames collects the set of variable names declared by a
+// module's configuration, which is the universe Resolve needs to consider
+// - anything not declared there can never be set regardless of what a
+// source offers for it.
+func resolveVariableNames(variables []*config.Variable) []string {
+	names := make([]string, len(variables))
+	for i, v := range variables {
+		names[i] = v.Name
+	}
+	return names
+}
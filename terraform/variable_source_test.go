@@ -0,0 +1,187 @@
+package terraform
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFileVariableSource(t *testing.T) {
+	source := &FileVariableSource{
+		Path:   "terraform.tfvars",
+		Values: map[string]interface{}{"region": "us-east-1"},
+	}
+
+	if got, want := source.Name(), "terraform.tfvars"; got != want {
+		t.Fatalf("got Name() %q, want %q", got, want)
+	}
+
+	v, ok, err := source.VariableValue("region")
+	if err != nil || !ok || v != "us-east-1" {
+		t.Fatalf("got v=%#v ok=%v err=%v", v, ok, err)
+	}
+
+	if _, ok, err := source.VariableValue("missing"); err != nil || ok {
+		t.Fatalf("expected ok=false for a variable the file doesn't set, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestStdinVariableSource_usesPromptWhenSet(t *testing.T) {
+	calls := 0
+	source := &StdinVariableSource{
+		Prompt: func(name string) (string, error) {
+			calls++
+			return "from-prompt", nil
+		},
+	}
+
+	v, ok, err := source.VariableValue("name")
+	if err != nil || !ok || v != "from-prompt" {
+		t.Fatalf("got v=%#v ok=%v err=%v", v, ok, err)
+	}
+
+	// A second lookup should hit the cached Answers instead of prompting again.
+	if _, _, err := source.VariableValue("name"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected exactly one prompt call, got %d", calls)
+	}
+}
+
+func TestStdinVariableSource_fallsBackToInputOutput(t *testing.T) {
+	var output bytes.Buffer
+	source := &StdinVariableSource{
+		Input:  strings.NewReader("from-input\n"),
+		Output: &output,
+	}
+
+	v, ok, err := source.VariableValue("name")
+	if err != nil || !ok || v != "from-input" {
+		t.Fatalf("got v=%#v ok=%v err=%v", v, ok, err)
+	}
+
+	if !strings.Contains(output.String(), "name") {
+		t.Fatalf("expected the prompt text written to Output to mention the variable name, got %q", output.String())
+	}
+}
+
+func TestStdinVariableSource_noPromptOrInput(t *testing.T) {
+	source := &StdinVariableSource{}
+
+	if _, ok, err := source.VariableValue("name"); err != nil || ok {
+		t.Fatalf("expected ok=false with neither Prompt nor Input configured, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestVariableSources_resolvePrecedence(t *testing.T) {
+	sources := VariableSources{
+		&LiteralVariableSource{SourceName: "defaults", Values: map[string]interface{}{
+			"name": "default-name",
+		}},
+		&EnvVariableSource{Environ: []string{"TF_VAR_name=env-name"}},
+		&LiteralVariableSource{SourceName: "-var", Values: map[string]interface{}{
+			"name": "cli-name",
+		}},
+	}
+
+	got, err := sources.Resolve([]string{"name"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got["name"] != "cli-name" {
+		t.Fatalf("expected the last source to win, got %#v", got["name"])
+	}
+}
+
+func TestVariableSources_resolveDeepMerge(t *testing.T) {
+	sources := VariableSources{
+		&LiteralVariableSource{SourceName: "defaults", Values: map[string]interface{}{
+			"tags": map[string]interface{}{
+				"env": "dev",
+				"nested": map[string]interface{}{
+					"a": "1",
+					"b": "2",
+				},
+			},
+		}},
+		&LiteralVariableSource{SourceName: "override", Values: map[string]interface{}{
+			"tags": map[string]interface{}{
+				"owner": "team-x",
+				"nested": map[string]interface{}{
+					"b": "override-2",
+				},
+			},
+		}},
+	}
+
+	got, err := sources.Resolve([]string{"tags"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := map[string]interface{}{
+		"env":   "dev",
+		"owner": "team-x",
+		"nested": map[string]interface{}{
+			"a": "1",
+			"b": "override-2",
+		},
+	}
+
+	if !reflect.DeepEqual(got["tags"], want) {
+		t.Fatalf("got %#v, want %#v", got["tags"], want)
+	}
+}
+
+func TestVariableSources_resolveOnlyQueriesRequestedNames(t *testing.T) {
+	calls := map[string]int{}
+	remote := &CachingRemoteVariableSource{
+		SourceName: "remote",
+		Remote: remoteFunc(func(name string) (interface{}, bool, error) {
+			calls[name]++
+			return "secret", true, nil
+		}),
+	}
+
+	sources := VariableSources{remote}
+	if _, err := sources.Resolve([]string{"used"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if calls["unused"] != 0 {
+		t.Fatalf("expected the remote source never to be queried for an unreferenced variable")
+	}
+	if calls["used"] != 1 {
+		t.Fatalf("expected exactly one fetch for a referenced variable, got %d", calls["used"])
+	}
+}
+
+func TestCachingRemoteVariableSource_cachesPerName(t *testing.T) {
+	calls := 0
+	remote := &CachingRemoteVariableSource{
+		SourceName: "remote",
+		Remote: remoteFunc(func(name string) (interface{}, bool, error) {
+			calls++
+			return "secret", true, nil
+		}),
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, ok, err := remote.VariableValue("db_password"); err != nil || !ok {
+			t.Fatalf("unexpected result: ok=%v err=%v", ok, err)
+		}
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected the remote provider to be fetched once, got %d calls", calls)
+	}
+}
+
+type remoteFunc func(name string) (interface{}, bool, error)
+
+func (f remoteFunc) FetchVariable(name string) (interface{}, bool, error) {
+	return f(name)
+}